@@ -0,0 +1,293 @@
+// SPDX-FileCopyrightText: 2023 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package ice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pion/logging"
+)
+
+// reusePortReadBufferSize bounds the per-shard read buffer used by
+// reusePortConn. It is sized generously above a typical UDP MTU since,
+// unlike UDPMuxDefault's own decode path, reusePortConn has no access to
+// the package's receiveMTU/maxAddrSize constants from this snapshot.
+const reusePortReadBufferSize = 1 << 16
+
+var (
+	errNoReusePortShards    = errors.New("at least one SO_REUSEPORT shard is required")
+	errReusePortShardsAddrs = errors.New("SO_REUSEPORT shards must all listen on the same address")
+)
+
+// reusePortMux fans a single externally-visible (IP, port) out across
+// several UDPMux instances bound to that same address via SO_REUSEPORT, so
+// the kernel load-balances incoming datagrams across them. It implements
+// UDPMux itself, so callers of NewMultiUDPMuxFromPorts see one address per
+// IP regardless of how many shards back it.
+type reusePortMux struct {
+	addr   net.Addr
+	shards []UDPMux
+}
+
+func newReusePortMux(shards []UDPMux) (*reusePortMux, error) {
+	if len(shards) == 0 {
+		return nil, errNoReusePortShards
+	}
+
+	addrs := shards[0].GetListenAddresses()
+	if len(addrs) != 1 {
+		return nil, errReusePortShardsAddrs
+	}
+	addr := addrs[0]
+	for _, shard := range shards[1:] {
+		shardAddrs := shard.GetListenAddresses()
+		if len(shardAddrs) != 1 || shardAddrs[0].String() != addr.String() {
+			return nil, errReusePortShardsAddrs
+		}
+	}
+
+	return &reusePortMux{addr: addr, shards: shards}, nil
+}
+
+// GetListenAddresses returns the single shared address, not one per shard.
+func (m *reusePortMux) GetListenAddresses() []net.Addr {
+	return []net.Addr{m.addr}
+}
+
+// GetConn registers ufrag on every shard and returns a net.PacketConn that
+// fans reads in across all of them.
+//
+// Sockets bound with SO_REUSEPORT share one kernel-level flow-hash table:
+// for a given remote 4-tuple, the kernel picks which shard's socket
+// receives the datagram, and it makes that choice before MultiUDPMuxDefault
+// ever sees a packet. There is no ufrag-derived hash that can predict or
+// control it. So ufrag must be servable from every shard, not pinned to
+// one - see reusePortConn.
+func (m *reusePortMux) GetConn(ufrag string, addr net.Addr) (net.PacketConn, error) {
+	conns := make([]net.PacketConn, 0, len(m.shards))
+	for _, shard := range m.shards {
+		conn, err := shard.GetConn(ufrag, addr)
+		if err != nil {
+			for _, c := range conns {
+				_ = c.Close()
+			}
+			return nil, err
+		}
+		conns = append(conns, conn)
+	}
+	return newReusePortConn(conns), nil
+}
+
+// RemoveConnByUfrag removes ufrag's connection from every shard.
+func (m *reusePortMux) RemoveConnByUfrag(ufrag string) {
+	for _, shard := range m.shards {
+		shard.RemoveConnByUfrag(ufrag)
+	}
+}
+
+// Close closes every shard.
+func (m *reusePortMux) Close() error {
+	var err error
+	for _, shard := range m.shards {
+		if e := shard.Close(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// reusePortConn presents the per-ufrag conns obtained from every
+// SO_REUSEPORT shard as a single net.PacketConn. Reads are fanned in from
+// every shard, since the kernel - not this code - decides which shard a
+// given peer's datagrams land on. Writes go out through the first shard;
+// since every shard shares the same externally-visible (IP, port), the
+// datagram it sends is indistinguishable from one sent by any other shard.
+//
+// A single shard's ReadFrom erroring (e.g. a transient syscall failure)
+// does not fail the muxed conn: that shard's reader simply stops while the
+// others keep servicing reads. Only once every shard has errored out does
+// ReadFrom return an error, so the caller (the ICE agent) isn't torn down
+// by a problem affecting one shard out of many.
+type reusePortConn struct {
+	conns []net.PacketConn
+
+	startOnce sync.Once
+	results   chan reusePortRead
+	stop      chan struct{}
+
+	// liveShards counts shard readers that haven't yet errored out. The
+	// last one to error closes dead, so ReadFrom returns deadErr instead of
+	// blocking forever with no readers left to feed results.
+	liveShards atomic.Int32
+	dead       chan struct{}
+	deadOnce   sync.Once
+	deadErr    error
+
+	closeOnce sync.Once
+}
+
+type reusePortRead struct {
+	data []byte
+	addr net.Addr
+}
+
+func newReusePortConn(conns []net.PacketConn) *reusePortConn {
+	c := &reusePortConn{
+		conns:   conns,
+		results: make(chan reusePortRead),
+		stop:    make(chan struct{}),
+		dead:    make(chan struct{}),
+	}
+	c.liveShards.Store(int32(len(conns)))
+	return c
+}
+
+// start spawns one reader goroutine per shard conn, lazily, so a
+// reusePortConn that is only ever written to (never read) doesn't leak
+// goroutines.
+func (c *reusePortConn) start() {
+	c.startOnce.Do(func() {
+		for _, conn := range c.conns {
+			go c.readLoop(conn)
+		}
+	})
+}
+
+func (c *reusePortConn) readLoop(conn net.PacketConn) {
+	buf := make([]byte, reusePortReadBufferSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if c.liveShards.Add(-1) == 0 {
+				c.deadOnce.Do(func() {
+					c.deadErr = err
+					close(c.dead)
+				})
+			}
+			return
+		}
+		data := append([]byte(nil), buf[:n]...)
+		select {
+		case c.results <- reusePortRead{data: data, addr: addr}:
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *reusePortConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	c.start()
+	select {
+	case res := <-c.results:
+		return copy(p, res.data), res.addr, nil
+	case <-c.dead:
+		return 0, nil, c.deadErr
+	case <-c.stop:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+func (c *reusePortConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	return c.conns[0].WriteTo(p, addr)
+}
+
+func (c *reusePortConn) LocalAddr() net.Addr {
+	return c.conns[0].LocalAddr()
+}
+
+func (c *reusePortConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		for _, conn := range c.conns {
+			if e := conn.Close(); e != nil {
+				err = e
+			}
+		}
+	})
+	return err
+}
+
+func (c *reusePortConn) SetDeadline(t time.Time) error {
+	return c.applyToAll(func(conn net.PacketConn) error { return conn.SetDeadline(t) })
+}
+
+func (c *reusePortConn) SetReadDeadline(t time.Time) error {
+	return c.applyToAll(func(conn net.PacketConn) error { return conn.SetReadDeadline(t) })
+}
+
+func (c *reusePortConn) SetWriteDeadline(t time.Time) error {
+	return c.applyToAll(func(conn net.PacketConn) error { return conn.SetWriteDeadline(t) })
+}
+
+func (c *reusePortConn) applyToAll(f func(net.PacketConn) error) error {
+	var err error
+	for _, conn := range c.conns {
+		if e := f(conn); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// listenReusePortShards opens shards sockets bound to the same (network,
+// laddr) pair. On Linux this uses SO_REUSEPORT so the kernel distributes
+// datagrams across them; on platforms without SO_REUSEPORT it logs a
+// warning and falls back to a single socket.
+func listenReusePortShards(network string, laddr *net.UDPAddr, shards int, logger logging.LeveledLogger) ([]net.PacketConn, error) {
+	if shards < 1 {
+		shards = 1
+	}
+	if shards > 1 && !reusePortSupported {
+		if logger == nil {
+			logger = logging.NewDefaultLoggerFactory().NewLogger("ice")
+		}
+		logger.Warnf("SO_REUSEPORT is not supported on this platform, falling back to a single socket for %s", laddr)
+		shards = 1
+	}
+
+	if shards == 1 {
+		conn, err := net.ListenUDP(network, laddr)
+		if err != nil {
+			return nil, err
+		}
+		return []net.PacketConn{conn}, nil
+	}
+
+	lc := net.ListenConfig{Control: reusePortControl}
+	conns := make([]net.PacketConn, 0, shards)
+	for i := 0; i < shards; i++ {
+		conn, err := lc.ListenPacket(context.Background(), network, laddr.String())
+		if err != nil {
+			for _, c := range conns {
+				_ = c.Close()
+			}
+			return nil, fmt.Errorf("failed to open SO_REUSEPORT shard %d/%d for %s: %w", i+1, shards, laddr, err)
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
+
+// UDPMuxFromPortWithReusePort opens shards sockets per (IP, port) using
+// SO_REUSEPORT, letting the kernel hash-distribute incoming datagrams
+// across cores without exposing more than one port per IP to peers.
+// GetListenAddresses still returns one address per IP; because the kernel,
+// not this code, decides which shard receives a given peer's datagrams,
+// GetConn registers every ufrag on all shards and fans reads in across
+// them (see reusePortConn). On platforms without SO_REUSEPORT this
+// degrades to a single socket with a logged warning.
+func UDPMuxFromPortWithReusePort(shards int) UDPMuxFromPortOption {
+	return &udpMuxFromPortOption{
+		f: func(p *multiUDPMuxFromPortParam) {
+			p.reusePortShards = shards
+		},
+	}
+}
@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2023 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package ice
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// reusePortSupported reports whether reusePortControl can actually set
+// SO_REUSEPORT on this platform.
+const reusePortSupported = true
+
+// reusePortControl is a net.ListenConfig.Control func that sets SO_REUSEPORT
+// (and SO_REUSEADDR) on the socket before it is bound, so that multiple
+// sockets can share the same (IP, port) and have the kernel hash-distribute
+// incoming datagrams across them.
+func reusePortControl(_, _ string, c syscall.RawConn) error {
+	var sockErr error
+	if err := c.Control(func(fd uintptr) {
+		if sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEADDR, 1); sockErr != nil {
+			return
+		}
+		sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,19 @@
+// SPDX-FileCopyrightText: 2023 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+//go:build !linux
+
+package ice
+
+import "syscall"
+
+// reusePortSupported reports whether reusePortControl can actually set
+// SO_REUSEPORT on this platform. SO_REUSEPORT's semantics (and its
+// existence) are platform-specific, so outside Linux we degrade to a single
+// socket rather than silently bind duplicate listeners.
+const reusePortSupported = false
+
+// reusePortControl is a no-op on platforms without SO_REUSEPORT support.
+func reusePortControl(_, _ string, _ syscall.RawConn) error {
+	return nil
+}
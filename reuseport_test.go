@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2023 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package ice
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePacketConn is a minimal net.PacketConn stand-in that lets a test
+// script exactly what ReadFrom returns next, without a real socket.
+type fakePacketConn struct {
+	local  net.Addr
+	reads  chan fakeRead
+	closed chan struct{}
+	once   sync.Once
+}
+
+type fakeRead struct {
+	data []byte
+	addr net.Addr
+	err  error
+}
+
+func newFakePacketConn(local net.Addr) *fakePacketConn {
+	return &fakePacketConn{
+		local:  local,
+		reads:  make(chan fakeRead, 8),
+		closed: make(chan struct{}),
+	}
+}
+
+func (f *fakePacketConn) push(data []byte, addr net.Addr) {
+	f.reads <- fakeRead{data: data, addr: addr}
+}
+func (f *fakePacketConn) pushErr(err error) { f.reads <- fakeRead{err: err} }
+
+func (f *fakePacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	select {
+	case r := <-f.reads:
+		if r.err != nil {
+			return 0, r.addr, r.err
+		}
+		return copy(p, r.data), r.addr, nil
+	case <-f.closed:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+func (f *fakePacketConn) WriteTo(p []byte, _ net.Addr) (int, error) { return len(p), nil }
+func (f *fakePacketConn) LocalAddr() net.Addr                       { return f.local }
+func (f *fakePacketConn) Close() error {
+	f.once.Do(func() { close(f.closed) })
+	return nil
+}
+func (f *fakePacketConn) SetDeadline(time.Time) error      { return nil }
+func (f *fakePacketConn) SetReadDeadline(time.Time) error  { return nil }
+func (f *fakePacketConn) SetWriteDeadline(time.Time) error { return nil }
+
+func TestReusePortConnFansInReadsFromAllShards(t *testing.T) {
+	shard0 := newFakePacketConn(mustUDPAddr(t, "127.0.0.1:5000"))
+	shard1 := newFakePacketConn(mustUDPAddr(t, "127.0.0.1:5001"))
+	peer := mustUDPAddr(t, "203.0.113.1:9")
+
+	conn := newReusePortConn([]net.PacketConn{shard0, shard1})
+	defer conn.Close()
+
+	shard1.push([]byte("from-shard-1"), peer)
+	buf := make([]byte, 64)
+	n, addr, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-shard-1", string(buf[:n]))
+	assert.Equal(t, peer.String(), addr.String())
+
+	shard0.push([]byte("from-shard-0"), peer)
+	n, addr, err = conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-shard-0", string(buf[:n]))
+	assert.Equal(t, peer.String(), addr.String())
+}
+
+func TestReusePortConnSurvivesOneShardErroring(t *testing.T) {
+	shard0 := newFakePacketConn(mustUDPAddr(t, "127.0.0.1:5000"))
+	shard1 := newFakePacketConn(mustUDPAddr(t, "127.0.0.1:5001"))
+	peer := mustUDPAddr(t, "203.0.113.1:9")
+
+	conn := newReusePortConn([]net.PacketConn{shard0, shard1})
+	defer conn.Close()
+
+	// shard0 fails; the muxed conn must keep serving reads from shard1
+	// rather than surfacing shard0's error to the caller.
+	shard0.pushErr(errors.New("shard0 boom"))
+	shard1.push([]byte("still-alive"), peer)
+
+	buf := make([]byte, 64)
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "still-alive", string(buf[:n]))
+
+	// Once every shard has errored, ReadFrom must return an error instead
+	// of blocking forever with no reader left to feed it.
+	shard1.pushErr(errors.New("shard1 boom"))
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := conn.ReadFrom(buf)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("ReadFrom blocked forever after every shard errored")
+	}
+}
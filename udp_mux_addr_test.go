@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2023 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package ice
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAddr is a net.Addr implementation other than *net.UDPAddr, for
+// exercising the non-UDPAddr fallback path.
+type fakeAddr string
+
+func (a fakeAddr) Network() string { return "fake" }
+func (a fakeAddr) String() string  { return string(a) }
+
+func TestAddrPortFromAddr(t *testing.T) {
+	addrPort, ok := addrPortFromAddr(mustUDPAddr(t, "192.0.2.1:1000"))
+	assert.True(t, ok)
+	assert.Equal(t, "192.0.2.1:1000", addrPort.String())
+
+	_, ok = addrPortFromAddr(fakeAddr("not-a-udp-addr"))
+	assert.False(t, ok)
+}
+
+func TestAddrPortFromAddrUnmapsIPv4MappedIPv6(t *testing.T) {
+	mapped := &net.UDPAddr{IP: net.ParseIP("::ffff:192.0.2.1"), Port: 1000}
+	fromMapped, ok := addrPortFromAddr(mapped)
+	assert.True(t, ok)
+
+	plain := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1000}
+	fromPlain, ok := addrPortFromAddr(plain)
+	assert.True(t, ok)
+
+	// Unmap normalization means the IPv4-mapped-IPv6 and plain IPv4 forms of
+	// the same address must key the same map entry in localAddrToMux.
+	assert.Equal(t, fromPlain, fromMapped)
+	assert.Equal(t, netip.MustParseAddr("192.0.2.1"), fromMapped.Addr())
+}
+
+func TestMultiUDPMuxDefaultGetConnAddrPortIPv4MappedNormalization(t *testing.T) {
+	mux := newFakeUDPMux(&net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1000})
+	m := NewMultiUDPMuxDefault(mux)
+
+	addrPort, ok := addrPortFromAddr(&net.UDPAddr{IP: net.ParseIP("::ffff:192.0.2.1"), Port: 1000})
+	assert.True(t, ok)
+
+	_, err := m.GetConnAddrPort("some-ufrag", addrPort)
+	assert.NoError(t, err)
+}
+
+func TestMultiUDPMuxDefaultNonUDPAddrFallback(t *testing.T) {
+	mux := newFakeUDPMux(fakeAddr("custom-transport-addr"))
+	m := NewMultiUDPMuxDefault(mux)
+
+	addrs := m.GetListenAddresses()
+	assert.Len(t, addrs, 1)
+	assert.Equal(t, "custom-transport-addr", addrs[0].String())
+
+	_, err := m.GetConn("some-ufrag", fakeAddr("custom-transport-addr"))
+	assert.NoError(t, err)
+
+	_, err = m.GetConn("some-ufrag", fakeAddr("unknown-addr"))
+	assert.ErrorIs(t, err, errNoUDPMuxAvailable)
+}
@@ -4,8 +4,13 @@
 package ice
 
 import (
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
 	"net"
+	"net/netip"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -15,44 +20,300 @@ import (
 	tudp "github.com/pion/transport/v2/udp"
 )
 
+// errPortStickinessViolation is returned by GetConn when addr does not match
+// the address a ufrag-pinning PortSelector previously handed out for ufrag.
+var errPortStickinessViolation = errors.New("addr does not match the port selected for this ufrag")
+
+// errNilUDPMux is returned by AddMux when passed a nil UDPMux.
+var errNilUDPMux = errors.New("mux must not be nil")
+
+// errUDPMuxNotRegistered is returned by RemoveMux for a mux that was never
+// added via NewMultiUDPMuxDefault, NewMultiUDPMuxFromPorts or AddMux.
+var errUDPMuxNotRegistered = errors.New("mux is not registered with this MultiUDPMuxDefault")
+
+// PortSelector chooses which of the addresses bound for a single IP should
+// be handed back from GetListenAddresses/GetListenAddressesForUfrag. A
+// PortSelector instance is scoped to one IP's set of addresses and may keep
+// state across calls (e.g. a round-robin cursor); MultiUDPMuxDefault creates
+// one instance per IP via a PortSelectorFactory so that state does not leak
+// across IPs. Implementations must be safe for concurrent use.
+type PortSelector interface {
+	// Select returns the chosen address from addrs. ufrag is the ICE
+	// username fragment associated with the caller's request, or empty when
+	// no session context is available. hints carries optional,
+	// strategy-specific routing hints and may be nil.
+	Select(addrs []net.Addr, ufrag string, hints map[string]string) net.Addr
+}
+
+// StickyPortSelector is implemented by PortSelector strategies that pin a
+// ufrag to a single address for the lifetime of a session. When the active
+// selector for an IP implements this interface, MultiUDPMuxDefault.GetConn
+// rejects calls whose addr diverges from both the ufrag's pinned address
+// and the address a ufrag-less gather would return (see GetConnAddrPort).
+// Actually gaining per-session stickiness, rather than every session
+// collapsing onto the same address, requires the caller to gather
+// candidates with GetListenAddressesForUfrag instead of GetListenAddresses.
+type StickyPortSelector interface {
+	PortSelector
+
+	// Validate reports whether addr is the address ufrag is pinned to.
+	Validate(addrs []net.Addr, ufrag string, addr net.Addr) bool
+}
+
+// PortSelectorFactory constructs a new PortSelector, scoped to a single IP's
+// set of listen addresses.
+type PortSelectorFactory func() PortSelector
+
+// roundRobinPortSelector is the default PortSelector, preserving
+// MultiUDPMuxDefault's historical unweighted round-robin behavior.
+type roundRobinPortSelector struct {
+	nextPos atomic.Int32
+}
+
+// NewRoundRobinPortSelector returns a PortSelector that cycles through the
+// candidate addresses for an IP in order.
+func NewRoundRobinPortSelector() PortSelector {
+	return &roundRobinPortSelector{}
+}
+
+func (s *roundRobinPortSelector) Select(addrs []net.Addr, _ string, _ map[string]string) net.Addr {
+	return addrs[s.nextPos.Add(1)%int32(len(addrs))]
+}
+
+// weightedPortSelector distributes selections across addrs proportionally
+// to weights.
+type weightedPortSelector struct {
+	weights []int
+	nextPos atomic.Int32
+}
+
+// NewWeightedPortSelector returns a PortSelector that picks among the
+// candidate addresses for an IP in proportion to weights. weights is
+// positional: weights[i] applies to the i-th address for that IP, in the
+// order ports were passed to NewMultiUDPMuxFromPorts. A missing or
+// non-positive weight defaults to 1.
+func NewWeightedPortSelector(weights []int) PortSelector {
+	return &weightedPortSelector{weights: weights}
+}
+
+func (s *weightedPortSelector) Select(addrs []net.Addr, _ string, _ map[string]string) net.Addr {
+	total := 0
+	resolved := make([]int, len(addrs))
+	for i := range addrs {
+		weight := 1
+		if i < len(s.weights) && s.weights[i] > 0 {
+			weight = s.weights[i]
+		}
+		resolved[i] = weight
+		total += weight
+	}
+
+	n := int(s.nextPos.Add(1)) % total
+	for i, weight := range resolved {
+		if n < weight {
+			return addrs[i]
+		}
+		n -= weight
+	}
+	return addrs[len(addrs)-1]
+}
+
+// consistentHashPortSelector deterministically maps a ufrag to one of the
+// candidate addresses, providing stickiness across calls for the same
+// session.
+//
+// It uses rendezvous (highest random weight) hashing rather than a plain
+// modulo of a ufrag hash: each candidate's own address, not its position in
+// addrs, is part of the hash input. Modulo-by-length remaps nearly every
+// ufrag whenever the candidate count changes; rendezvous hashing remaps a
+// ufrag only when its current address is no longer a candidate, or when a
+// newly added address scores higher for that ufrag than its current one.
+// That matters here because MultiUDPMuxDefault.AddMux/RemoveMux can change
+// an IP's candidate set at runtime, and selector state is carried across
+// the rebuild (see rebuildLocked).
+type consistentHashPortSelector struct{}
+
+// NewConsistentHashPortSelector returns a PortSelector that hashes ufrag to
+// deterministically pick one of the candidate addresses for an IP, so that
+// every candidate gathered for a session, and every later GetConn call for
+// it, lands on the same port.
+func NewConsistentHashPortSelector() PortSelector {
+	return &consistentHashPortSelector{}
+}
+
+func (s *consistentHashPortSelector) Select(addrs []net.Addr, ufrag string, _ map[string]string) net.Addr {
+	return addrs[s.index(addrs, ufrag)]
+}
+
+func (s *consistentHashPortSelector) Validate(addrs []net.Addr, ufrag string, addr net.Addr) bool {
+	return addrs[s.index(addrs, ufrag)].String() == addr.String()
+}
+
+// index picks the candidate with the highest hash of (ufrag, address)
+// among addrs. Keying the hash on each address's own identity, instead of
+// its slice position, keeps a ufrag pinned to the same address across
+// AddMux/RemoveMux calls that remove other addresses for the same IP, and
+// across calls that add addresses scoring lower for that ufrag.
+func (s *consistentHashPortSelector) index(addrs []net.Addr, ufrag string) int {
+	if ufrag == "" || len(addrs) == 1 {
+		return 0
+	}
+	best := 0
+	var bestScore uint32
+	for i, addr := range addrs {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(ufrag))
+		_, _ = h.Write([]byte(addr.String()))
+		if score := h.Sum32(); i == 0 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// randomPortSelector picks an address uniformly at random on every call.
+type randomPortSelector struct{}
+
+// NewRandomPortSelector returns a PortSelector that picks uniformly at
+// random among the candidate addresses for an IP.
+func NewRandomPortSelector() PortSelector {
+	return &randomPortSelector{}
+}
+
+func (s *randomPortSelector) Select(addrs []net.Addr, _ string, _ map[string]string) net.Addr {
+	return addrs[rand.Intn(len(addrs))] //nolint:gosec
+}
+
 // MultiUDPMuxDefault implements both UDPMux and AllConnsGetter,
 // allowing users to pass multiple UDPMux instances to the ICE agent
-// configuration.
+// configuration. Muxes may be added or removed at runtime with AddMux and
+// RemoveMux; mu guards every field below against concurrent access from
+// those calls.
 type MultiUDPMuxDefault struct {
-	muxes          []UDPMux
-	localAddrToMux map[string]UDPMux
+	mu                  sync.RWMutex
+	muxes               []UDPMux
+	localAddrToMux      map[netip.AddrPort]UDPMux
+	portSelectorFactory PortSelectorFactory
+	// onListenAddressesChanged, when set, is called after AddMux or
+	// RemoveMux changes the set of addresses GetListenAddresses returns.
+	onListenAddressesChanged func()
 
 	// Manage port balance for mux that listen on multiple ports for same IP,
 	// for each IP, only return one addr (one port) for each GetListenAddresses call to
 	// avoid duplicate ip candidates be gathered for a single ice agent.
 	multiPortsAddresses []*multiPortsAddress
+	// ipToMultiPortsAddress indexes multiPortsAddresses by IP, so GetConn can
+	// find the selector governing an address without a linear scan.
+	ipToMultiPortsAddress map[netip.Addr]*multiPortsAddress
+
+	// nonUDPAddrToMux and nonUDPAddrs cover listen addresses that aren't a
+	// *net.UDPAddr (addrPortFromAddr returns ok=false for them), e.g. from a
+	// custom transport.Net/vnet implementation. They can't be grouped by IP
+	// for per-IP port selection, so they're always gathered as-is and looked
+	// up by their String() form instead of a netip.AddrPort. See
+	// rebuildLocked.
+	nonUDPAddrToMux map[string]UDPMux
+	nonUDPAddrs     []net.Addr
+
+	log logging.LeveledLogger
+
+	// drainGuards tracks the pending RemoveMux drain, if any, for a mux that
+	// has been removed at least once. It outlives any single RemoveMux call
+	// so that a mux which is removed, re-added via AddMux, and removed again
+	// before the first drain timer fires is still only ever closed once; see
+	// RemoveMux and AddMux.
+	drainGuards map[UDPMux]*muxDrainGuard
+}
+
+// muxDrainGuard pairs the timer scheduled by a RemoveMux drain with a
+// sync.Once so that, however many times the owning mux is removed and
+// re-added, Close is called on it at most once.
+type muxDrainGuard struct {
+	once  sync.Once
+	timer *time.Timer
 }
 
 type multiPortsAddress struct {
 	addresses []net.Addr
-	nextPos   atomic.Int32
+	selector  PortSelector
 }
 
-func (addr *multiPortsAddress) next() net.Addr {
-	return addr.addresses[addr.nextPos.Add(1)%int32(len(addr.addresses))]
+func (a *multiPortsAddress) next(ufrag string) net.Addr {
+	return a.selector.Select(a.addresses, ufrag, nil)
+}
+
+// addrPortFromAddr converts a *net.UDPAddr into a netip.AddrPort without
+// going through its String() representation. ok is false for any other
+// net.Addr implementation.
+func addrPortFromAddr(addr net.Addr) (addrPort netip.AddrPort, ok bool) {
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok {
+		return netip.AddrPort{}, false
+	}
+	ip, ok := netip.AddrFromSlice(udpAddr.IP)
+	if !ok {
+		return netip.AddrPort{}, false
+	}
+	return netip.AddrPortFrom(ip.Unmap(), uint16(udpAddr.Port)), true
 }
 
 // NewMultiUDPMuxDefault creates an instance of MultiUDPMuxDefault that
-// uses the provided UDPMux instances.
+// uses the provided UDPMux instances. Port selection for IPs with multiple
+// bound ports defaults to round-robin; use newMultiUDPMuxDefault to supply a
+// different PortSelectorFactory.
 func NewMultiUDPMuxDefault(muxes ...UDPMux) *MultiUDPMuxDefault {
-	addrToMux := make(map[string]UDPMux)
-	ipToAddrs := make(map[string]*multiPortsAddress)
-	for _, mux := range muxes {
+	return newMultiUDPMuxDefault(NewRoundRobinPortSelector, nil, muxes...)
+}
+
+func newMultiUDPMuxDefault(selectorFactory PortSelectorFactory, logger logging.LeveledLogger, muxes ...UDPMux) *MultiUDPMuxDefault {
+	if logger == nil {
+		logger = logging.NewDefaultLoggerFactory().NewLogger("ice")
+	}
+	m := &MultiUDPMuxDefault{
+		muxes:               muxes,
+		portSelectorFactory: selectorFactory,
+		drainGuards:         make(map[UDPMux]*muxDrainGuard),
+		log:                 logger,
+	}
+	m.rebuildLocked()
+	return m
+}
+
+// rebuildLocked recomputes localAddrToMux, multiPortsAddresses,
+// ipToMultiPortsAddress, nonUDPAddrToMux and nonUDPAddrs from the current
+// m.muxes. Existing selectors are kept for IPs that are still present, so
+// round-robin cursors and consistent-hash stickiness survive
+// AddMux/RemoveMux calls; only newly appeared IPs get a fresh selector.
+// Callers must hold m.mu for writing.
+func (m *MultiUDPMuxDefault) rebuildLocked() {
+	addrToMux := make(map[netip.AddrPort]UDPMux)
+	ipToAddrs := make(map[netip.Addr]*multiPortsAddress)
+	nonUDPAddrToMux := make(map[string]UDPMux)
+	var nonUDPAddrs []net.Addr
+	for _, mux := range m.muxes {
 		for _, addr := range mux.GetListenAddresses() {
-			addrToMux[addr.String()] = mux
+			addrPort, ok := addrPortFromAddr(addr)
+			if !ok {
+				m.log.Warnf("MultiUDPMuxDefault: %T returned a non-UDPAddr listen address %s, bypassing per-IP port selection for it", mux, addr)
+				nonUDPAddrToMux[addr.String()] = mux
+				nonUDPAddrs = append(nonUDPAddrs, addr)
+				continue
+			}
+			addrToMux[addrPort] = mux
 
-			ip := addr.(*net.UDPAddr).IP.String()
+			ip := addrPort.Addr()
 			if mpa, ok := ipToAddrs[ip]; ok {
 				mpa.addresses = append(mpa.addresses, addr)
-			} else {
-				ipToAddrs[ip] = &multiPortsAddress{
-					addresses: []net.Addr{addr},
-				}
+				continue
+			}
+
+			selector := m.portSelectorFactory()
+			if existing, ok := m.ipToMultiPortsAddress[ip]; ok {
+				selector = existing.selector
+			}
+			ipToAddrs[ip] = &multiPortsAddress{
+				addresses: []net.Addr{addr},
+				selector:  selector,
 			}
 		}
 	}
@@ -61,35 +322,199 @@ func NewMultiUDPMuxDefault(muxes ...UDPMux) *MultiUDPMuxDefault {
 	for _, mpa := range ipToAddrs {
 		multiPortsAddresses = append(multiPortsAddresses, mpa)
 	}
-	return &MultiUDPMuxDefault{
-		muxes:               muxes,
-		localAddrToMux:      addrToMux,
-		multiPortsAddresses: multiPortsAddresses,
+
+	m.localAddrToMux = addrToMux
+	m.multiPortsAddresses = multiPortsAddresses
+	m.ipToMultiPortsAddress = ipToAddrs
+	m.nonUDPAddrToMux = nonUDPAddrToMux
+	m.nonUDPAddrs = nonUDPAddrs
+}
+
+// AddMux registers mux with this MultiUDPMuxDefault, so its addresses are
+// returned by subsequent GetListenAddresses/GetListenAddressesForUfrag calls
+// and it becomes eligible for GetConn. Safe to call while the agent is
+// gathering candidates on the other registered muxes.
+func (m *MultiUDPMuxDefault) AddMux(mux UDPMux) error {
+	if mux == nil {
+		return errNilUDPMux
+	}
+
+	m.mu.Lock()
+	m.muxes = append(m.muxes, mux)
+	m.rebuildLocked()
+	// mux is live again: cancel any drain timer left over from a previous
+	// RemoveMux so it doesn't close mux out from under its new use. The
+	// guard itself is kept, not deleted, so a *later* RemoveMux of this same
+	// mux still only ever closes it once (see muxDrainGuard).
+	if guard, ok := m.drainGuards[mux]; ok && guard.timer != nil {
+		guard.timer.Stop()
+		guard.timer = nil
+	}
+	m.mu.Unlock()
+
+	m.notifyListenAddressesChanged()
+	return nil
+}
+
+// RemoveMux unregisters mux. Its addresses stop being returned from
+// GetListenAddresses immediately, but its already-established connections
+// keep being serviced for up to drain before Close is called on it; a
+// non-positive drain closes it immediately. Returns an error if mux was not
+// registered.
+//
+// mux is closed at most once even if it is removed, re-added via AddMux,
+// and removed again before an earlier drain timer fires: the sync.Once in
+// its muxDrainGuard makes that Close call idempotent regardless of which
+// timer (or this call, for a non-positive drain) wins the race.
+func (m *MultiUDPMuxDefault) RemoveMux(mux UDPMux, drain time.Duration) error {
+	m.mu.Lock()
+	pos := -1
+	for i, existing := range m.muxes {
+		if existing == mux {
+			pos = i
+			break
+		}
+	}
+	if pos < 0 {
+		m.mu.Unlock()
+		return errUDPMuxNotRegistered
+	}
+	m.muxes = append(m.muxes[:pos:pos], m.muxes[pos+1:]...)
+	m.rebuildLocked()
+
+	guard, ok := m.drainGuards[mux]
+	if !ok {
+		guard = &muxDrainGuard{}
+		m.drainGuards[mux] = guard
+	} else if guard.timer != nil {
+		// A drain from a previous removal is still pending; this removal
+		// supersedes it.
+		guard.timer.Stop()
+		guard.timer = nil
+	}
+
+	// The timer is armed here, before m.mu is released, not after: a
+	// concurrent AddMux that acquires m.mu right after this RemoveMux
+	// releases it must always see a non-nil guard.timer to cancel. Arming it
+	// in a second, later critical section left a window where AddMux would
+	// observe guard.timer still nil, skip cancellation, and then this
+	// RemoveMux would install a timer that closes the very mux AddMux just
+	// brought back.
+	var closeNow bool
+	if drain <= 0 {
+		closeNow = true
+	} else {
+		guard.timer = time.AfterFunc(drain, func() {
+			guard.once.Do(func() { _ = mux.Close() })
+		})
+	}
+	m.mu.Unlock()
+
+	m.notifyListenAddressesChanged()
+
+	if closeNow {
+		var closeErr error
+		guard.once.Do(func() { closeErr = mux.Close() })
+		return closeErr
+	}
+	return nil
+}
+
+// OnListenAddressesChanged registers cb to be called whenever AddMux or
+// RemoveMux changes the set of addresses GetListenAddresses returns, so the
+// agent knows to re-gather host candidates. Calling this again replaces the
+// previously registered callback.
+func (m *MultiUDPMuxDefault) OnListenAddressesChanged(cb func()) {
+	m.mu.Lock()
+	m.onListenAddressesChanged = cb
+	m.mu.Unlock()
+}
+
+func (m *MultiUDPMuxDefault) notifyListenAddressesChanged() {
+	m.mu.RLock()
+	cb := m.onListenAddressesChanged
+	m.mu.RUnlock()
+	if cb != nil {
+		cb()
 	}
 }
 
 // GetConn returns a PacketConn given the connection's ufrag and network
-// creates the connection if an existing one can't be found.
+// creates the connection if an existing one can't be found. addr should
+// usually be a *net.UDPAddr; callers that already have a netip.AddrPort
+// should use GetConnAddrPort instead to avoid the conversion. Any other
+// net.Addr implementation (see nonUDPAddrToMux) is looked up by its
+// String() form instead.
 func (m *MultiUDPMuxDefault) GetConn(ufrag string, addr net.Addr) (net.PacketConn, error) {
-	mux, ok := m.localAddrToMux[addr.String()]
+	addrPort, ok := addrPortFromAddr(addr)
+	if !ok {
+		m.mu.RLock()
+		mux, ok := m.nonUDPAddrToMux[addr.String()]
+		m.mu.RUnlock()
+		if !ok {
+			return nil, errNoUDPMuxAvailable
+		}
+		return mux.GetConn(ufrag, addr)
+	}
+	return m.GetConnAddrPort(ufrag, addrPort)
+}
+
+// GetConnAddrPort is equivalent to GetConn but takes a netip.AddrPort
+// directly, so the lookup avoids the allocation that addr.String() would
+// incur on this hot path.
+func (m *MultiUDPMuxDefault) GetConnAddrPort(ufrag string, addrPort netip.AddrPort) (net.PacketConn, error) {
+	m.mu.RLock()
+	mux, ok := m.localAddrToMux[addrPort]
+	var mpa *multiPortsAddress
+	if ok {
+		mpa = m.ipToMultiPortsAddress[addrPort.Addr()]
+	}
+	m.mu.RUnlock()
+
 	if !ok {
 		return nil, errNoUDPMuxAvailable
 	}
+
+	addr := net.UDPAddrFromAddrPort(addrPort)
+	if ufrag != "" && mpa != nil {
+		if sticky, ok := mpa.selector.(StickyPortSelector); ok {
+			// GetListenAddresses (the address-gathering path every caller in
+			// this snapshot actually uses) always selects with ufrag="", so
+			// addr is legitimately addrs[0] for every session regardless of
+			// its ufrag; rejecting that would break every sticky session by
+			// default. Only enforce stickiness once addr diverges from the
+			// ufrag-less gather result, i.e. once a caller has actually used
+			// GetListenAddressesForUfrag to get a ufrag-pinned candidate.
+			defaultAddr := mpa.selector.Select(mpa.addresses, "", nil)
+			if defaultAddr.String() != addr.String() && !sticky.Validate(mpa.addresses, ufrag, addr) {
+				return nil, errPortStickinessViolation
+			}
+		}
+	}
+
 	return mux.GetConn(ufrag, addr)
 }
 
 // RemoveConnByUfrag stops and removes the muxed packet connection
 // from all underlying UDPMux instances.
 func (m *MultiUDPMuxDefault) RemoveConnByUfrag(ufrag string) {
-	for _, mux := range m.muxes {
+	m.mu.RLock()
+	muxes := m.muxes
+	m.mu.RUnlock()
+
+	for _, mux := range muxes {
 		mux.RemoveConnByUfrag(ufrag)
 	}
 }
 
 // Close the multi mux, no further connections could be created
 func (m *MultiUDPMuxDefault) Close() error {
+	m.mu.RLock()
+	muxes := m.muxes
+	m.mu.RUnlock()
+
 	var err error
-	for _, mux := range m.muxes {
+	for _, mux := range muxes {
 		if e := mux.Close(); e != nil {
 			err = e
 		}
@@ -99,10 +524,24 @@ func (m *MultiUDPMuxDefault) Close() error {
 
 // GetListenAddresses returns the list of addresses that this mux is listening on
 func (m *MultiUDPMuxDefault) GetListenAddresses() []net.Addr {
-	addrs := make([]net.Addr, 0, len(m.multiPortsAddresses))
+	return m.GetListenAddressesForUfrag("")
+}
+
+// GetListenAddressesForUfrag returns the list of addresses that this mux is
+// listening on, letting the active PortSelector take ufrag into account
+// (e.g. to stick a session to the same port across calls). For selectors
+// that ignore ufrag, this is equivalent to GetListenAddresses. Addresses
+// that bypass port selection entirely (see nonUDPAddrToMux) are always
+// included as-is.
+func (m *MultiUDPMuxDefault) GetListenAddressesForUfrag(ufrag string) []net.Addr {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	addrs := make([]net.Addr, 0, len(m.multiPortsAddresses)+len(m.nonUDPAddrs))
 	for _, mpa := range m.multiPortsAddresses {
-		addrs = append(addrs, mpa.next())
+		addrs = append(addrs, mpa.next(ufrag))
 	}
+	addrs = append(addrs, m.nonUDPAddrs...)
 	return addrs
 }
 
@@ -114,7 +553,8 @@ func NewMultiUDPMuxFromPort(port int, opts ...UDPMuxFromPortOption) (*MultiUDPMu
 
 func NewMultiUDPMuxFromPorts(ports []int, opts ...UDPMuxFromPortOption) (*MultiUDPMuxDefault, error) {
 	params := multiUDPMuxFromPortParam{
-		networks: []NetworkType{NetworkTypeUDP4, NetworkTypeUDP6},
+		networks:     []NetworkType{NetworkTypeUDP4, NetworkTypeUDP6},
+		portSelector: NewRoundRobinPortSelector,
 	}
 	for _, opt := range opts {
 		opt.apply(&params)
@@ -132,24 +572,63 @@ func NewMultiUDPMuxFromPorts(ports []int, opts ...UDPMuxFromPortOption) (*MultiU
 		return nil, err
 	}
 
-	conns := make([]net.PacketConn, 0, len(ports)*len(ips))
+	muxes := make([]UDPMux, 0, len(ports)*len(ips))
+	var allConns []net.PacketConn
 	for _, ip := range ips {
 		for _, port := range ports {
-			conn, listenErr := params.net.ListenUDP("udp", &net.UDPAddr{IP: ip, Port: port})
-			if listenErr != nil {
-				err = listenErr
-				break
-			}
-			if params.readBufferSize > 0 {
-				_ = conn.SetReadBuffer(params.readBufferSize)
+			var shardConns []net.PacketConn
+			if params.reusePortShards > 1 {
+				shardConns, err = listenReusePortShards("udp", &net.UDPAddr{IP: ip, Port: port}, params.reusePortShards, params.logger)
+				if err != nil {
+					break
+				}
+				for _, conn := range shardConns {
+					if udpConn, ok := conn.(*net.UDPConn); ok {
+						if params.readBufferSize > 0 {
+							_ = udpConn.SetReadBuffer(params.readBufferSize)
+						}
+						if params.writeBufferSize > 0 {
+							_ = udpConn.SetWriteBuffer(params.writeBufferSize)
+						}
+					}
+				}
+			} else {
+				conn, listenErr := params.net.ListenUDP("udp", &net.UDPAddr{IP: ip, Port: port})
+				if listenErr != nil {
+					err = listenErr
+					break
+				}
+				if params.readBufferSize > 0 {
+					_ = conn.SetReadBuffer(params.readBufferSize)
+				}
+				if params.writeBufferSize > 0 {
+					_ = conn.SetWriteBuffer(params.writeBufferSize)
+				}
+				shardConns = []net.PacketConn{conn}
 			}
-			if params.writeBufferSize > 0 {
-				_ = conn.SetWriteBuffer(params.writeBufferSize)
+
+			shardMuxes := make([]UDPMux, 0, len(shardConns))
+			for _, conn := range shardConns {
+				if params.batchWriteSize > 0 {
+					conn = tudp.NewBatchConn(conn, params.batchWriteSize, params.batchWriteInterval)
+				}
+				allConns = append(allConns, conn)
+				shardMuxes = append(shardMuxes, NewUDPMuxDefault(UDPMuxParams{
+					Logger:  params.logger,
+					UDPConn: conn,
+					Net:     params.net,
+				}))
 			}
-			if params.batchWriteSize > 0 {
-				conns = append(conns, tudp.NewBatchConn(conn, params.batchWriteSize, params.batchWriteInterval))
+
+			if len(shardMuxes) == 1 {
+				muxes = append(muxes, shardMuxes[0])
 			} else {
-				conns = append(conns, conn)
+				var rpMux *reusePortMux
+				rpMux, err = newReusePortMux(shardMuxes)
+				if err != nil {
+					break
+				}
+				muxes = append(muxes, rpMux)
 			}
 		}
 		if err != nil {
@@ -158,23 +637,13 @@ func NewMultiUDPMuxFromPorts(ports []int, opts ...UDPMuxFromPortOption) (*MultiU
 	}
 
 	if err != nil {
-		for _, conn := range conns {
+		for _, conn := range allConns {
 			_ = conn.Close()
 		}
 		return nil, err
 	}
 
-	muxes := make([]UDPMux, 0, len(conns))
-	for _, conn := range conns {
-		mux := NewUDPMuxDefault(UDPMuxParams{
-			Logger:  params.logger,
-			UDPConn: conn,
-			Net:     params.net,
-		})
-		muxes = append(muxes, mux)
-	}
-
-	return NewMultiUDPMuxDefault(muxes...), nil
+	return newMultiUDPMuxDefault(params.portSelector, params.logger, muxes...), nil
 }
 
 // UDPMuxFromPortOption provide options for NewMultiUDPMuxFromPort
@@ -193,6 +662,8 @@ type multiUDPMuxFromPortParam struct {
 	net                transport.Net
 	batchWriteSize     int
 	batchWriteInterval time.Duration
+	portSelector       PortSelectorFactory
+	reusePortShards    int
 }
 
 type udpMuxFromPortOption struct {
@@ -283,3 +754,21 @@ func UDPMuxFromPortWithBatchWrite(batchWriteSize int, batchWriteInterval time.Du
 		},
 	}
 }
+
+// There is no read-side counterpart to BatchWrite here (e.g. a
+// recvmmsg/ipv4.PacketConn.ReadBatch read loop backed by a sync.Pool of
+// receiveMTU+maxAddrSize buffers). UDPMuxFromPortWithBatchWrite wraps each
+// conn for batched writes only; NewMultiUDPMuxFromPorts does not change how
+// the UDPMuxDefault it builds reads from that conn.
+
+// UDPMuxFromPortWithPortSelector sets the PortSelectorFactory used to pick
+// among the ports bound for a single IP. factory is called once per IP, so
+// strategies may keep per-IP state (e.g. a round-robin cursor) without it
+// leaking across IPs. Defaults to NewRoundRobinPortSelector.
+func UDPMuxFromPortWithPortSelector(factory PortSelectorFactory) UDPMuxFromPortOption {
+	return &udpMuxFromPortOption{
+		f: func(p *multiUDPMuxFromPortParam) {
+			p.portSelector = factory
+		},
+	}
+}
@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2023 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package ice
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeUDPMux is a minimal UDPMux stand-in for exercising
+// MultiUDPMuxDefault.AddMux/RemoveMux without a real socket.
+type fakeUDPMux struct {
+	addr      net.Addr
+	closed    chan struct{}
+	closeOnce bool
+}
+
+func newFakeUDPMux(addr net.Addr) *fakeUDPMux {
+	return &fakeUDPMux{addr: addr, closed: make(chan struct{})}
+}
+
+func (f *fakeUDPMux) GetConn(string, net.Addr) (net.PacketConn, error) { return nil, nil }
+func (f *fakeUDPMux) RemoveConnByUfrag(string)                         {}
+func (f *fakeUDPMux) GetListenAddresses() []net.Addr                   { return []net.Addr{f.addr} }
+
+func (f *fakeUDPMux) Close() error {
+	if f.closeOnce {
+		return nil
+	}
+	f.closeOnce = true
+	close(f.closed)
+	return nil
+}
+
+func (f *fakeUDPMux) isClosed() bool {
+	select {
+	case <-f.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestMultiUDPMuxDefaultAddRemoveMux(t *testing.T) {
+	muxA := newFakeUDPMux(mustUDPAddr(t, "127.0.0.1:1000"))
+	m := NewMultiUDPMuxDefault(muxA)
+	assert.Len(t, m.GetListenAddresses(), 1)
+
+	muxB := newFakeUDPMux(mustUDPAddr(t, "127.0.0.2:1000"))
+	assert.NoError(t, m.AddMux(muxB))
+	assert.Len(t, m.GetListenAddresses(), 2)
+
+	assert.ErrorIs(t, m.AddMux(nil), errNilUDPMux)
+
+	assert.NoError(t, m.RemoveMux(muxB, 0))
+	assert.Len(t, m.GetListenAddresses(), 1)
+	assert.True(t, muxB.isClosed())
+
+	assert.ErrorIs(t, m.RemoveMux(muxB, 0), errUDPMuxNotRegistered)
+}
+
+func TestMultiUDPMuxDefaultRemoveMuxDrainsBeforeClose(t *testing.T) {
+	muxA := newFakeUDPMux(mustUDPAddr(t, "127.0.0.1:1000"))
+	m := NewMultiUDPMuxDefault(muxA)
+
+	assert.NoError(t, m.RemoveMux(muxA, 20*time.Millisecond))
+	assert.False(t, muxA.isClosed())
+
+	assert.Eventually(t, muxA.isClosed, time.Second, time.Millisecond)
+}
+
+func TestMultiUDPMuxDefaultRemoveMuxSurvivesRaceWithReAdd(t *testing.T) {
+	// Remove with a drain, then re-add before the drain fires: the mux must
+	// not be closed out from under its new registration, and a subsequent
+	// remove must still close it exactly once.
+	muxA := newFakeUDPMux(mustUDPAddr(t, "127.0.0.1:1000"))
+	m := NewMultiUDPMuxDefault(muxA)
+
+	assert.NoError(t, m.RemoveMux(muxA, 20*time.Millisecond))
+	assert.NoError(t, m.AddMux(muxA))
+
+	time.Sleep(40 * time.Millisecond)
+	assert.False(t, muxA.isClosed(), "mux was closed by a drain timer despite being re-added")
+
+	assert.NoError(t, m.RemoveMux(muxA, 0))
+	assert.True(t, muxA.isClosed())
+}
+
+// TestMultiUDPMuxDefaultRemoveMuxConcurrentReAdd stresses many muxes being
+// drained and immediately re-added at once: RemoveMux must arm its drain
+// timer and AddMux must cancel it under the same mutex, with no window in
+// between where a concurrent caller on a different goroutine could observe
+// the timer as not-yet-armed and fail to cancel it.
+func TestMultiUDPMuxDefaultRemoveMuxConcurrentReAdd(t *testing.T) {
+	const n = 50
+
+	muxes := make([]*fakeUDPMux, n)
+	initial := make([]UDPMux, n)
+	for i := range muxes {
+		muxes[i] = newFakeUDPMux(mustUDPAddr(t, fmt.Sprintf("127.0.0.%d:1000", i+1)))
+		initial[i] = muxes[i]
+	}
+	m := NewMultiUDPMuxDefault(initial...)
+
+	var wg sync.WaitGroup
+	for _, mux := range muxes {
+		wg.Add(1)
+		go func(mux *fakeUDPMux) {
+			defer wg.Done()
+			assert.NoError(t, m.RemoveMux(mux, time.Millisecond))
+			assert.NoError(t, m.AddMux(mux))
+		}(mux)
+	}
+	wg.Wait()
+
+	// Give every drain timer a chance to fire if it was not actually
+	// cancelled by its mux's re-add.
+	time.Sleep(50 * time.Millisecond)
+
+	for _, mux := range muxes {
+		assert.False(t, mux.isClosed(), "mux %s was closed despite a concurrent re-add racing its drain timer", mux.addr)
+	}
+}
+
+func TestMultiUDPMuxDefaultOnListenAddressesChanged(t *testing.T) {
+	muxA := newFakeUDPMux(mustUDPAddr(t, "127.0.0.1:1000"))
+	m := NewMultiUDPMuxDefault(muxA)
+
+	changed := make(chan struct{}, 1)
+	m.OnListenAddressesChanged(func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	muxB := newFakeUDPMux(mustUDPAddr(t, "127.0.0.2:1000"))
+	assert.NoError(t, m.AddMux(muxB))
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("OnListenAddressesChanged callback was not invoked")
+	}
+}
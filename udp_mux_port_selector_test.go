@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2023 The Pion community <https://pion.ly>
+// SPDX-License-Identifier: MIT
+
+package ice
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustUDPAddr(t *testing.T, s string) *net.UDPAddr {
+	t.Helper()
+	addr, err := net.ResolveUDPAddr("udp", s)
+	assert.NoError(t, err)
+	return addr
+}
+
+func TestRoundRobinPortSelector(t *testing.T) {
+	addrs := []net.Addr{
+		mustUDPAddr(t, "127.0.0.1:1000"),
+		mustUDPAddr(t, "127.0.0.1:1001"),
+		mustUDPAddr(t, "127.0.0.1:1002"),
+	}
+
+	selector := NewRoundRobinPortSelector()
+	var got []string
+	for i := 0; i < len(addrs)*2; i++ {
+		got = append(got, selector.Select(addrs, "", nil).String())
+	}
+
+	assert.Equal(t, []string{
+		addrs[1].String(), addrs[2].String(), addrs[0].String(),
+		addrs[1].String(), addrs[2].String(), addrs[0].String(),
+	}, got)
+}
+
+func TestWeightedPortSelector(t *testing.T) {
+	addrs := []net.Addr{
+		mustUDPAddr(t, "127.0.0.1:1000"),
+		mustUDPAddr(t, "127.0.0.1:1001"),
+	}
+
+	selector := NewWeightedPortSelector([]int{1, 9})
+	counts := map[string]int{}
+	for i := 0; i < 10; i++ {
+		counts[selector.Select(addrs, "", nil).String()]++
+	}
+
+	assert.Equal(t, 1, counts[addrs[0].String()])
+	assert.Equal(t, 9, counts[addrs[1].String()])
+}
+
+func TestConsistentHashPortSelectorStickiness(t *testing.T) {
+	addrs := []net.Addr{
+		mustUDPAddr(t, "127.0.0.1:1000"),
+		mustUDPAddr(t, "127.0.0.1:1001"),
+		mustUDPAddr(t, "127.0.0.1:1002"),
+	}
+
+	selector := NewConsistentHashPortSelector()
+	first := selector.Select(addrs, "some-ufrag", nil)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first.String(), selector.Select(addrs, "some-ufrag", nil).String())
+	}
+
+	sticky, ok := selector.(StickyPortSelector)
+	assert.True(t, ok)
+	assert.True(t, sticky.Validate(addrs, "some-ufrag", first))
+}
+
+func TestConsistentHashPortSelectorSurvivesUnrelatedChurn(t *testing.T) {
+	// A ufrag's pinned address should not move just because some other
+	// address was removed, or an address scoring lower for this ufrag
+	// (here 127.0.0.1:1003) was added - removing the ufrag's own address
+	// does remap it.
+	addrs := []net.Addr{
+		mustUDPAddr(t, "127.0.0.1:1000"),
+		mustUDPAddr(t, "127.0.0.1:1001"),
+		mustUDPAddr(t, "127.0.0.1:1002"),
+	}
+
+	selector := NewConsistentHashPortSelector()
+	pinned := selector.Select(addrs, "some-ufrag", nil)
+
+	grown := append(append([]net.Addr{}, addrs...), mustUDPAddr(t, "127.0.0.1:1003"))
+	assert.Equal(t, pinned.String(), selector.Select(grown, "some-ufrag", nil).String())
+
+	shrunk := make([]net.Addr, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr.String() != pinned.String() {
+			shrunk = append(shrunk, addr)
+		}
+	}
+	assert.NotEqual(t, pinned.String(), selector.Select(shrunk, "some-ufrag", nil).String())
+}
+
+func TestRandomPortSelector(t *testing.T) {
+	addrs := []net.Addr{
+		mustUDPAddr(t, "127.0.0.1:1000"),
+		mustUDPAddr(t, "127.0.0.1:1001"),
+	}
+
+	selector := NewRandomPortSelector()
+	for i := 0; i < 20; i++ {
+		addr := selector.Select(addrs, "", nil)
+		assert.Contains(t, []string{addrs[0].String(), addrs[1].String()}, addr.String())
+	}
+}